@@ -0,0 +1,25 @@
+// kibosh
+//
+// Copyright (c) 2017-Present Pivotal Software, Inc. All Rights Reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may
+// obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the
+// License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+// ClusterContext names one kubeconfig context Kibosh should drive Tiller on,
+// along with the selector the broker uses to route a service instance to it.
+// An empty list of contexts on the top level Config means Kibosh keeps its
+// original single-cluster behavior.
+type ClusterContext struct {
+	Name     string            `yaml:"name"`
+	Selector map[string]string `yaml:"selector"`
+}
@@ -0,0 +1,62 @@
+// kibosh
+//
+// Copyright (c) 2017-Present Pivotal Software, Inc. All Rights Reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may
+// obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the
+// License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import "github.com/pkg/errors"
+
+// TillerTLSConfig carries the CA/cert/key material Kibosh uses to provision
+// Tiller with TLS enabled, and to dial it back over mTLS afterwards. Scope:
+// operator-supplied PEMs only - Kibosh does not generate certificates itself,
+// so every field below must be set when Enabled is true. An external cert
+// pipeline (e.g. cert-manager) is still required to produce them.
+type TillerTLSConfig struct {
+	Enabled    bool   `yaml:"enabled"`
+	VerifyTLS  bool   `yaml:"verify_tls"`
+	CACert     string `yaml:"ca_cert"`
+	ServerCert string `yaml:"server_cert"`
+	ServerKey  string `yaml:"server_key"`
+	ClientCert string `yaml:"client_cert"`
+	ClientKey  string `yaml:"client_key"`
+}
+
+// HasTillerTLSConfig reports whether TLS-secured Tiller install was
+// requested, mirroring RegistryConfig.HasRegistryConfig's nil-safety.
+func (c *TillerTLSConfig) HasTillerTLSConfig() bool {
+	return c != nil && c.Enabled
+}
+
+// Validate checks that every PEM Tiller TLS needs was actually supplied. It's
+// a no-op when TLS isn't enabled.
+func (c *TillerTLSConfig) Validate() error {
+	if !c.HasTillerTLSConfig() {
+		return nil
+	}
+
+	missing := map[string]string{
+		"ca_cert":     c.CACert,
+		"server_cert": c.ServerCert,
+		"server_key":  c.ServerKey,
+		"client_cert": c.ClientCert,
+		"client_key":  c.ClientKey,
+	}
+	for field, value := range missing {
+		if value == "" {
+			return errors.Errorf("tiller tls is enabled but %s is empty", field)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,59 @@
+// kibosh
+//
+// Copyright (c) 2017-Present Pivotal Software, Inc. All Rights Reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may
+// obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the
+// License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import "testing"
+
+func TestTillerTLSConfigValidate(t *testing.T) {
+	complete := func() *TillerTLSConfig {
+		return &TillerTLSConfig{
+			Enabled:    true,
+			CACert:     "ca",
+			ServerCert: "server-cert",
+			ServerKey:  "server-key",
+			ClientCert: "client-cert",
+			ClientKey:  "client-key",
+		}
+	}
+
+	t.Run("nil config is valid", func(t *testing.T) {
+		var c *TillerTLSConfig
+		if err := c.Validate(); err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("disabled config is valid even with empty fields", func(t *testing.T) {
+		c := &TillerTLSConfig{Enabled: false}
+		if err := c.Validate(); err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("enabled with all fields set is valid", func(t *testing.T) {
+		if err := complete().Validate(); err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("enabled with a missing field is rejected", func(t *testing.T) {
+		c := complete()
+		c.CACert = ""
+		if err := c.Validate(); err == nil {
+			t.Error("expected an error for missing ca_cert, got nil")
+		}
+	})
+}
@@ -0,0 +1,51 @@
+// kibosh
+//
+// Copyright (c) 2017-Present Pivotal Software, Inc. All Rights Reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may
+// obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the
+// License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helm
+
+import (
+	"context"
+
+	helmstaller "k8s.io/helm/cmd/helm/installer"
+)
+
+// MyHelmClient is the subset of the upstream helm client the installer drives
+// Tiller through: installing/upgrading it, listing what's deployed (to check
+// compatibility), and reconfiguring for TLS after a secret rotation.
+type MyHelmClient interface {
+	Install(ctx context.Context, options *helmstaller.Options) error
+	Upgrade(ctx context.Context, options *helmstaller.Options) error
+	ListReleases(ctx context.Context) (*ListReleasesResponse, error)
+	ConfigureTLS(certPEM string, keyPEM string, caPEM string) error
+}
+
+// ListReleasesResponse, Release, Chart and ChartMetadata mirror the shape of
+// the upstream Tiller ListReleases response that releaseTillerConstraints
+// reads a tillerVersion constraint out of.
+type ListReleasesResponse struct {
+	Releases []*Release
+}
+
+type Release struct {
+	Chart *Chart
+}
+
+type Chart struct {
+	Metadata *ChartMetadata
+}
+
+type ChartMetadata struct {
+	TillerVersion string
+}
@@ -0,0 +1,116 @@
+// kibosh
+//
+// Copyright (c) 2017-Present Pivotal Software, Inc. All Rights Reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may
+// obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the
+// License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helm
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Masterminds/semver"
+	"github.com/pkg/errors"
+)
+
+// InvalidUpgradeError is returned when CompatibilityChecker rejects a Tiller
+// upgrade, so callers can surface an actionable message.
+type InvalidUpgradeError struct {
+	Current    string
+	Target     string
+	Constraint string
+	Reason     string
+}
+
+func (e *InvalidUpgradeError) Error() string {
+	return fmt.Sprintf("cannot upgrade tiller from %s to %s: %s (constraint %s)", e.Current, e.Target, e.Reason, e.Constraint)
+}
+
+// maxMinorStep is the largest minor version jump a single upgrade may make.
+const maxMinorStep = 1
+
+// CompatibilityChecker gates a Tiller upgrade against a supported version
+// range, a max minor-version step, and deployed releases' tillerVersion
+// constraints.
+type CompatibilityChecker struct {
+	SupportedRange string
+}
+
+func NewCompatibilityChecker(supportedRange string) *CompatibilityChecker {
+	return &CompatibilityChecker{
+		SupportedRange: supportedRange,
+	}
+}
+
+// Check validates upgrading from currentVersion to targetVersion given the
+// tillerVersion constraints (if any) declared by currently deployed releases.
+func (c *CompatibilityChecker) Check(currentVersion string, targetVersion string, releaseConstraints []string) error {
+	target, err := semver.NewVersion(targetVersion)
+	if err != nil {
+		return &InvalidUpgradeError{Current: currentVersion, Target: targetVersion, Reason: "target version is not valid semver"}
+	}
+
+	if c.SupportedRange != "" {
+		supported, err := semver.NewConstraint(c.SupportedRange)
+		if err != nil {
+			return &InvalidUpgradeError{Current: currentVersion, Target: targetVersion, Constraint: c.SupportedRange, Reason: "configured supported range is not valid"}
+		}
+		if !supported.Check(target) {
+			return &InvalidUpgradeError{Current: currentVersion, Target: targetVersion, Constraint: c.SupportedRange, Reason: "target version is outside the supported range"}
+		}
+	}
+
+	current, err := semver.NewVersion(currentVersion)
+	if err == nil {
+		if target.Major() == current.Major() && target.Minor()-current.Minor() > maxMinorStep {
+			return &InvalidUpgradeError{Current: currentVersion, Target: targetVersion, Reason: "upgrade jumps more than one minor version"}
+		}
+		if target.Major() > current.Major() {
+			return &InvalidUpgradeError{Current: currentVersion, Target: targetVersion, Reason: "upgrade crosses a major version"}
+		}
+	}
+
+	for _, rawConstraint := range releaseConstraints {
+		if rawConstraint == "" {
+			continue
+		}
+		constraint, err := semver.NewConstraint(rawConstraint)
+		if err != nil {
+			return &InvalidUpgradeError{Current: currentVersion, Target: targetVersion, Constraint: rawConstraint, Reason: "release declares an invalid tillerVersion constraint"}
+		}
+		if !constraint.Check(target) {
+			return &InvalidUpgradeError{Current: currentVersion, Target: targetVersion, Constraint: rawConstraint, Reason: "target version does not satisfy a deployed release's tillerVersion constraint"}
+		}
+	}
+
+	return nil
+}
+
+// releaseTillerConstraints collects the tillerVersion constraint (if any)
+// declared by each currently deployed release's chart.
+func (i *installer) releaseTillerConstraints(ctx context.Context) ([]string, error) {
+	resp, err := i.client.ListReleases(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "error listing releases for tiller compatibility check")
+	}
+
+	var constraints []string
+	for _, rel := range resp.Releases {
+		if rel.Chart == nil || rel.Chart.Metadata == nil || rel.Chart.Metadata.TillerVersion == "" {
+			continue
+		}
+		constraints = append(constraints, rel.Chart.Metadata.TillerVersion)
+	}
+
+	return constraints, nil
+}
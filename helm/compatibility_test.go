@@ -0,0 +1,89 @@
+// kibosh
+//
+// Copyright (c) 2017-Present Pivotal Software, Inc. All Rights Reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may
+// obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the
+// License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helm
+
+import "testing"
+
+func TestCompatibilityCheckerCheck(t *testing.T) {
+	cases := []struct {
+		name               string
+		supportedRange     string
+		current            string
+		target             string
+		releaseConstraints []string
+		expectErr          bool
+	}{
+		{
+			name:    "patch upgrade with no constraints is allowed",
+			current: "2.9.0",
+			target:  "2.9.1",
+		},
+		{
+			name:    "minor step of one is allowed",
+			current: "2.9.0",
+			target:  "2.10.0",
+		},
+		{
+			name:      "minor step greater than one is rejected",
+			current:   "2.9.0",
+			target:    "2.11.0",
+			expectErr: true,
+		},
+		{
+			name:      "major version jump is rejected",
+			current:   "2.9.0",
+			target:    "3.0.0",
+			expectErr: true,
+		},
+		{
+			name:           "target outside supported range is rejected",
+			supportedRange: "<2.10.0",
+			current:        "2.9.0",
+			target:         "2.10.0",
+			expectErr:      true,
+		},
+		{
+			name:           "target inside supported range is allowed",
+			supportedRange: "<2.10.0",
+			current:        "2.9.0",
+			target:         "2.9.5",
+		},
+		{
+			name:               "release tillerVersion constraint satisfied",
+			current:            "2.9.0",
+			target:             "2.10.0",
+			releaseConstraints: []string{">=2.9.0"},
+		},
+		{
+			name:               "release tillerVersion constraint violated",
+			current:            "2.9.0",
+			target:             "2.10.0",
+			releaseConstraints: []string{"<2.10.0"},
+			expectErr:          true,
+		},
+	}
+
+	for _, c := range cases {
+		checker := NewCompatibilityChecker(c.supportedRange)
+		err := checker.Check(c.current, c.target, c.releaseConstraints)
+		if c.expectErr && err == nil {
+			t.Errorf("%s: expected an error, got nil", c.name)
+		}
+		if !c.expectErr && err != nil {
+			t.Errorf("%s: expected no error, got %v", c.name, err)
+		}
+	}
+}
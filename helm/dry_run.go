@@ -0,0 +1,80 @@
+// kibosh
+//
+// Copyright (c) 2017-Present Pivotal Software, Inc. All Rights Reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may
+// obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the
+// License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helm
+
+import (
+	"encoding/json"
+
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+	helmstaller "k8s.io/helm/cmd/helm/installer"
+)
+
+// OutputFormat selects how RenderedManifests encodes the dry-run output.
+type OutputFormat string
+
+const (
+	OutputFormatYAML OutputFormat = "yaml"
+	OutputFormatJSON OutputFormat = "json"
+)
+
+// renderManifests builds the Tiller Deployment, Service and ServiceAccount
+// that Install() would otherwise apply, and encodes them in the requested
+// OutputFormat, so operators can preview exactly what Kibosh would do -
+// including registry rewrites and replica/TLS options - before touching the
+// cluster.
+func renderManifests(options *helmstaller.Options, format OutputFormat) ([]byte, error) {
+	objects := []interface{}{
+		helmstaller.ServiceAccount(options.ServiceAccount, options.Namespace),
+		helmstaller.Deployment(options),
+		helmstaller.Service(options.Namespace),
+	}
+
+	var rendered []byte
+	for _, obj := range objects {
+		var chunk []byte
+		var err error
+		if format == OutputFormatJSON {
+			chunk, err = json.MarshalIndent(obj, "", "  ")
+		} else {
+			chunk, err = yaml.Marshal(obj)
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "error rendering tiller manifest")
+		}
+
+		if format != OutputFormatJSON && len(rendered) > 0 {
+			rendered = append(rendered, []byte("---\n")...)
+		}
+		rendered = append(rendered, chunk...)
+	}
+
+	return rendered, nil
+}
+
+func (i *installer) SetDryRun(dryRun bool) {
+	i.dryRun = dryRun
+}
+
+func (i *installer) SetOutputFormat(format OutputFormat) {
+	i.outputFormat = format
+}
+
+// RenderedManifests returns the manifests produced by the most recent dry-run
+// Install/InstallContext call. It is empty until a dry-run has been run.
+func (i *installer) RenderedManifests() []byte {
+	return i.renderedManifests
+}
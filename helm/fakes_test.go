@@ -0,0 +1,88 @@
+// kibosh
+//
+// Copyright (c) 2017-Present Pivotal Software, Inc. All Rights Reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may
+// obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the
+// License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helm
+
+import (
+	"context"
+
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	api_v1 "k8s.io/client-go/pkg/api/v1"
+	"k8s.io/client-go/pkg/apis/extensions/v1beta1"
+	helmstaller "k8s.io/helm/cmd/helm/installer"
+)
+
+// fakeHelmClient is a MyHelmClient fake that records how many times Install,
+// Upgrade and ConfigureTLS were called, so tests can assert on InstallContext/RotateTLS's
+// decision logic without a real Tiller.
+type fakeHelmClient struct {
+	installErr        error
+	installCalls      int
+	upgradeErr        error
+	upgradeCalls      int
+	listReleasesResp  *ListReleasesResponse
+	listReleasesErr   error
+	configureTLSErr   error
+	configureTLSCalls int
+}
+
+func (f *fakeHelmClient) Install(ctx context.Context, options *helmstaller.Options) error {
+	f.installCalls++
+	return f.installErr
+}
+
+func (f *fakeHelmClient) Upgrade(ctx context.Context, options *helmstaller.Options) error {
+	f.upgradeCalls++
+	return f.upgradeErr
+}
+
+func (f *fakeHelmClient) ListReleases(ctx context.Context) (*ListReleasesResponse, error) {
+	if f.listReleasesResp == nil {
+		return &ListReleasesResponse{}, f.listReleasesErr
+	}
+	return f.listReleasesResp, f.listReleasesErr
+}
+
+func (f *fakeHelmClient) ConfigureTLS(certPEM string, keyPEM string, caPEM string) error {
+	f.configureTLSCalls++
+	return f.configureTLSErr
+}
+
+// fakeCluster is a k8s.Cluster fake covering the deployment/secret calls the
+// installer and TLSSecretSetup make.
+type fakeCluster struct {
+	deployment         *v1beta1.Deployment
+	getDeploymentErr   error
+	getDeploymentCalls int
+	createSecretErr    error
+	createSecretCalls  int
+	deleteSecretErr    error
+	deleteSecretCalls  int
+}
+
+func (f *fakeCluster) GetDeployment(ctx context.Context, namespace string, name string, options meta_v1.GetOptions) (*v1beta1.Deployment, error) {
+	f.getDeploymentCalls++
+	return f.deployment, f.getDeploymentErr
+}
+
+func (f *fakeCluster) CreateSecret(ctx context.Context, namespace string, secret *api_v1.Secret) (*api_v1.Secret, error) {
+	f.createSecretCalls++
+	return secret, f.createSecretErr
+}
+
+func (f *fakeCluster) DeleteSecret(ctx context.Context, namespace string, name string, options *meta_v1.DeleteOptions) error {
+	f.deleteSecretCalls++
+	return f.deleteSecretErr
+}
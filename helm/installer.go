@@ -16,6 +16,7 @@
 package helm
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -31,16 +32,32 @@ import (
 )
 
 type installer struct {
-	maxWait        time.Duration
-	registryConfig *config.RegistryConfig
-	cluster        k8s.Cluster
-	client         MyHelmClient
-	logger         lager.Logger
+	maxWait           time.Duration
+	replicas          int32
+	forceUpgrade      bool
+	dryRun            bool
+	outputFormat      OutputFormat
+	renderedManifests []byte
+	compatChecker     *CompatibilityChecker
+	tlsConfig         *config.TillerTLSConfig
+	registryConfig    *config.RegistryConfig
+	cluster           k8s.Cluster
+	client            MyHelmClient
+	logger            lager.Logger
 }
 
 type Installer interface {
 	Install() error
+	InstallContext(ctx context.Context) error
 	SetMaxWait(duration time.Duration)
+	SetReplicas(replicas int32)
+	SetForceUpgrade(force bool)
+	SetSupportedRange(supportedRange string)
+	SetDryRun(dryRun bool)
+	SetOutputFormat(format OutputFormat)
+	RenderedManifests() []byte
+	SetTLSConfig(tlsConfig *config.TillerTLSConfig)
+	RotateTLS(ctx context.Context) error
 }
 
 var (
@@ -56,6 +73,9 @@ const (
 func NewInstaller(registryConfig *config.RegistryConfig, cluster k8s.Cluster, client MyHelmClient, logger lager.Logger) Installer {
 	return &installer{
 		maxWait:        60 * time.Second,
+		replicas:       1,
+		outputFormat:   OutputFormatYAML,
+		compatChecker:  NewCompatibilityChecker(""),
 		registryConfig: registryConfig,
 		cluster:        cluster,
 		client:         client,
@@ -64,34 +84,64 @@ func NewInstaller(registryConfig *config.RegistryConfig, cluster k8s.Cluster, cl
 }
 
 func (i *installer) Install() error {
+	return i.InstallContext(context.Background())
+}
+
+func (i *installer) InstallContext(ctx context.Context) error {
 	i.logger.Debug(fmt.Sprintf("Installing helm with Tiller version %s", tillerTag))
 
 	tillerImage := "gcr.io/kubernetes-helm/tiller:" + tillerTag
 	if i.registryConfig.HasRegistryConfig() {
-		privateRegistrySetup := k8s.NewPrivateRegistrySetup("kube-system", serviceAccount, i.cluster, i.registryConfig)
-		err := privateRegistrySetup.Setup()
-		if err != nil {
-			return err
-		}
-
 		tillerImage = fmt.Sprintf("%s/tiller:%s", i.registryConfig.Server, tillerTag)
+
+		// Setup provisions a real ServiceAccount and pulls registry secrets
+		// into the cluster - a dry-run must only compute the rewritten image
+		// name above, never touch the cluster.
+		if !i.dryRun {
+			privateRegistrySetup := k8s.NewPrivateRegistrySetup("kube-system", serviceAccount, i.cluster, i.registryConfig)
+			if err := privateRegistrySetup.Setup(); err != nil {
+				return err
+			}
+		}
 	}
 
 	options := helmstaller.Options{
 		Namespace:      nameSpace,
 		ImageSpec:      tillerImage,
 		ServiceAccount: serviceAccount,
+		Replicas:       int(i.replicas),
 	}
 
-	err := i.client.Install(&options)
+	if i.dryRun {
+		// A dry-run must not touch the cluster: skip provisionTLS below
+		// (which creates/deletes a real Secret and reconfigures the live
+		// helm client) and only apply the TLS option fields, then render
+		// what Install would otherwise apply.
+		if err := i.tlsConfig.Validate(); err != nil {
+			return err
+		}
+		i.applyTLSOptions(&options)
+		rendered, err := renderManifests(&options, i.outputFormat)
+		if err != nil {
+			return err
+		}
+		i.renderedManifests = rendered
+		return nil
+	}
+
+	if err := i.provisionTLS(ctx, &options); err != nil {
+		return err
+	}
+
+	err := i.client.Install(ctx, &options)
 	if err != nil {
 		if !apierrors.IsAlreadyExists(err) {
-			return errors.Wrap(err, "Error installing new helm")
+			return wrapPhaseErr(ctx, err, "install")
 		}
 
-		obj, err := i.cluster.GetDeployment(nameSpace, deploymentName, meta_v1.GetOptions{})
+		obj, err := i.cluster.GetDeployment(ctx, nameSpace, deploymentName, meta_v1.GetOptions{})
 		if err != nil {
-			return err
+			return wrapPhaseErr(ctx, err, "install")
 		}
 		existingImage := obj.Spec.Template.Spec.Containers[0].Image
 		if existingImage == tillerImage {
@@ -100,49 +150,116 @@ func (i *installer) Install() error {
 		if !i.isNewerVersion(existingImage, tillerImage) {
 			return nil
 		}
-		err = i.client.Upgrade(&options)
+		if !i.forceUpgrade {
+			releaseConstraints, err := i.releaseTillerConstraints(ctx)
+			if err != nil {
+				return wrapPhaseErr(ctx, err, "upgrade")
+			}
+			if err := i.compatChecker.Check(imageVersion(existingImage), imageVersion(tillerImage), releaseConstraints); err != nil {
+				return err
+			}
+		}
+		err = i.client.Upgrade(ctx, &options)
 		if err != nil {
-			return errors.Wrap(err, "Error upgrading helm")
+			return wrapPhaseErr(ctx, err, "upgrade")
 		}
 	}
 
 	i.logger.Info("Waiting for tiller to become healthy")
+	ticker := time.NewTicker(healthTickInterval(i.maxWait))
+	defer ticker.Stop()
+
 	waited := time.Duration(0)
 	for {
-		if i.helmHealthy() {
-			break
+		if i.helmHealthy(ctx) {
+			return nil
 		}
 		if waited >= i.maxWait {
 			return errors.New("Didn't become healthy within max time")
 		}
-		willWait := i.maxWait / 10
-		waited = waited + willWait
-		time.Sleep(willWait)
+
+		select {
+		case <-ctx.Done():
+			return wrapPhaseErr(ctx, ctx.Err(), "wait-for-healthy")
+		case <-ticker.C:
+			waited = waited + healthTickInterval(i.maxWait)
+		}
 	}
-	return nil
+}
+
+// wrapPhaseErr annotates err with the Install phase it happened in, so a
+// cancelled context (broker shutdown) or a genuine Tiller failure can be told
+// apart by callers.
+func wrapPhaseErr(ctx context.Context, err error, phase string) error {
+	if ctx.Err() != nil {
+		return errors.Wrapf(ctx.Err(), "tiller %s interrupted", phase)
+	}
+	return errors.Wrapf(err, "Error during tiller %s", phase)
 }
 
 func (i *installer) SetMaxWait(wait time.Duration) {
 	i.maxWait = wait
 }
 
-func (i *installer) helmHealthy() bool {
-	_, err := i.client.ListReleases()
-	return err == nil
+func (i *installer) SetReplicas(replicas int32) {
+	i.replicas = replicas
 }
 
-func (i *installer) isNewerVersion(existingImage string, newImage string) bool {
-	existingVersionSplit := strings.Split(existingImage, ":")
-	if len(existingVersionSplit) < 2 {
+func (i *installer) SetForceUpgrade(force bool) {
+	i.forceUpgrade = force
+}
+
+// SetSupportedRange configures the Tiller version range the
+// CompatibilityChecker allows upgrades into, e.g. sourced from config. An
+// empty range (the default) leaves that check disabled.
+func (i *installer) SetSupportedRange(supportedRange string) {
+	i.compatChecker.SupportedRange = supportedRange
+}
+
+func (i *installer) helmHealthy(ctx context.Context) bool {
+	_, err := i.client.ListReleases(ctx)
+	if err != nil {
+		return false
+	}
+
+	if i.replicas <= 1 {
 		return true
 	}
-	existingVersion := existingVersionSplit[1]
 
-	newVersionSplit := strings.Split(newImage, ":")
-	if len(newVersionSplit) < 2 {
+	deployment, err := i.cluster.GetDeployment(ctx, nameSpace, deploymentName, meta_v1.GetOptions{})
+	if err != nil {
+		return false
+	}
+	return deployment.Status.ReadyReplicas >= i.replicas
+}
+
+func (i *installer) isNewerVersion(existingImage string, newImage string) bool {
+	existingVersion := imageVersion(existingImage)
+	newVersion := imageVersion(newImage)
+	if existingVersion == "" || newVersion == "" {
 		return true
 	}
-	newVersion := newVersionSplit[1]
 
 	return semver.MustParse(newVersion).GreaterThan(semver.MustParse(existingVersion))
 }
+
+func imageVersion(image string) string {
+	split := strings.Split(image, ":")
+	if len(split) < 2 {
+		return ""
+	}
+	return split[1]
+}
+
+// healthTickInterval returns how often InstallContext polls helmHealthy while
+// waiting for Tiller to come up. maxWait of zero (or anything under 10ns)
+// means "don't wait", but time.NewTicker panics on a non-positive interval,
+// so this floors it at a millisecond; the waited >= maxWait check in the poll
+// loop still fails fast in that case.
+func healthTickInterval(maxWait time.Duration) time.Duration {
+	interval := maxWait / 10
+	if interval <= 0 {
+		return time.Millisecond
+	}
+	return interval
+}
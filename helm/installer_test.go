@@ -0,0 +1,210 @@
+// kibosh
+//
+// Copyright (c) 2017-Present Pivotal Software, Inc. All Rights Reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may
+// obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the
+// License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helm
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"code.cloudfoundry.org/lager"
+	"github.com/cf-platform-eng/kibosh/config"
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	api_v1 "k8s.io/client-go/pkg/api/v1"
+	"k8s.io/client-go/pkg/apis/extensions/v1beta1"
+)
+
+func newTestInstaller(cluster *fakeCluster, client *fakeHelmClient) *installer {
+	return &installer{
+		maxWait:       time.Millisecond,
+		replicas:      1,
+		outputFormat:  OutputFormatYAML,
+		compatChecker: NewCompatibilityChecker(""),
+		tlsConfig:     &config.TillerTLSConfig{},
+		cluster:       cluster,
+		client:        client,
+		logger:        lager.NewLogger("test"),
+	}
+}
+
+func TestInstallContextDryRun(t *testing.T) {
+	t.Run("renders manifests without touching the cluster or helm client", func(t *testing.T) {
+		cluster := &fakeCluster{}
+		client := &fakeHelmClient{}
+		i := newTestInstaller(cluster, client)
+		i.dryRun = true
+
+		if err := i.InstallContext(context.Background()); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(i.RenderedManifests()) == 0 {
+			t.Error("expected dry-run to populate RenderedManifests")
+		}
+		if cluster.getDeploymentCalls != 0 || cluster.createSecretCalls != 0 || cluster.deleteSecretCalls != 0 {
+			t.Error("expected dry-run not to touch the cluster")
+		}
+		if client.installCalls != 0 || client.upgradeCalls != 0 {
+			t.Error("expected dry-run not to touch the helm client")
+		}
+	})
+}
+
+func alreadyExistsErr() error {
+	return apierrors.NewAlreadyExists(schema.GroupResource{Group: "extensions", Resource: "deployments"}, deploymentName)
+}
+
+func deploymentWithImage(image string) *v1beta1.Deployment {
+	deployment := &v1beta1.Deployment{}
+	deployment.Spec.Template.Spec.Containers = []api_v1.Container{{Image: image}}
+	return deployment
+}
+
+func TestInstallContextCompatibilityGate(t *testing.T) {
+	existingImage := "gcr.io/kubernetes-helm/tiller:2.9.0"
+
+	t.Run("blocks an incompatible upgrade using the deployed release's tillerVersion constraint", func(t *testing.T) {
+		cluster := &fakeCluster{deployment: deploymentWithImage(existingImage)}
+		client := &fakeHelmClient{
+			installErr: alreadyExistsErr(),
+			listReleasesResp: &ListReleasesResponse{Releases: []*Release{
+				{Chart: &Chart{Metadata: &ChartMetadata{TillerVersion: "<2.10.0"}}},
+			}},
+		}
+		i := newTestInstaller(cluster, client)
+		tillerTag = "2.10.0"
+		defer func() { tillerTag = "" }()
+
+		err := i.InstallContext(context.Background())
+		if err == nil {
+			t.Fatal("expected the upgrade to be rejected")
+		}
+		upgradeErr, ok := err.(*InvalidUpgradeError)
+		if !ok {
+			t.Fatalf("expected an *InvalidUpgradeError, got %T: %v", err, err)
+		}
+		if upgradeErr.Constraint != "<2.10.0" {
+			t.Errorf("expected the rejection to come from the release's tillerVersion constraint, got reason %q constraint %q", upgradeErr.Reason, upgradeErr.Constraint)
+		}
+		if client.upgradeCalls != 0 {
+			t.Error("expected Upgrade not to be called when the compatibility gate rejects the upgrade")
+		}
+	})
+
+	t.Run("forceUpgrade bypasses the compatibility gate", func(t *testing.T) {
+		cluster := &fakeCluster{deployment: deploymentWithImage(existingImage)}
+		client := &fakeHelmClient{
+			installErr: alreadyExistsErr(),
+			listReleasesResp: &ListReleasesResponse{Releases: []*Release{
+				{Chart: &Chart{Metadata: &ChartMetadata{TillerVersion: "<2.10.0"}}},
+			}},
+		}
+		i := newTestInstaller(cluster, client)
+		i.forceUpgrade = true
+		tillerTag = "2.10.0"
+		defer func() { tillerTag = "" }()
+
+		if err := i.InstallContext(context.Background()); err != nil {
+			t.Fatalf("expected force upgrade to bypass the compatibility gate, got %v", err)
+		}
+		if client.upgradeCalls != 1 {
+			t.Error("expected Upgrade to be called once")
+		}
+	})
+}
+
+func TestInstallContextCancelDuringHealthWait(t *testing.T) {
+	t.Run("returns promptly with ctx.Err wrapped with the wait-for-healthy phase", func(t *testing.T) {
+		cluster := &fakeCluster{}
+		client := &fakeHelmClient{listReleasesErr: errors.New("tiller not ready yet")}
+		i := newTestInstaller(cluster, client)
+		i.maxWait = time.Hour
+
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(10*time.Millisecond, cancel)
+
+		done := make(chan error, 1)
+		go func() { done <- i.InstallContext(ctx) }()
+
+		select {
+		case err := <-done:
+			if errors.Cause(err) != context.Canceled {
+				t.Fatalf("expected an error wrapping ctx.Err(), got %v", err)
+			}
+			if !strings.Contains(err.Error(), "wait-for-healthy") {
+				t.Errorf("expected the error to mention the wait-for-healthy phase, got %v", err)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("expected InstallContext to return promptly once ctx was cancelled")
+		}
+	})
+}
+
+func TestInstallContextReplicaHealthGate(t *testing.T) {
+	t.Run("waits for ReadyReplicas to catch up before reporting healthy", func(t *testing.T) {
+		cluster := &fakeCluster{deployment: &v1beta1.Deployment{}}
+		cluster.deployment.Status.ReadyReplicas = 1
+		client := &fakeHelmClient{}
+		i := newTestInstaller(cluster, client)
+		i.replicas = 3
+
+		if i.helmHealthy(context.Background()) {
+			t.Error("expected not healthy while ReadyReplicas is below the configured replica count")
+		}
+
+		cluster.deployment.Status.ReadyReplicas = 3
+		if !i.helmHealthy(context.Background()) {
+			t.Error("expected healthy once ReadyReplicas reaches the configured replica count")
+		}
+	})
+
+	t.Run("a single replica is healthy as soon as releases list successfully", func(t *testing.T) {
+		cluster := &fakeCluster{}
+		client := &fakeHelmClient{}
+		i := newTestInstaller(cluster, client)
+
+		if !i.helmHealthy(context.Background()) {
+			t.Error("expected healthy without checking deployment status when replicas <= 1")
+		}
+		if cluster.getDeploymentCalls != 0 {
+			t.Error("expected the single-replica path not to call GetDeployment")
+		}
+	})
+}
+
+func TestHealthTickInterval(t *testing.T) {
+	cases := []struct {
+		name     string
+		maxWait  time.Duration
+		expected time.Duration
+	}{
+		{name: "typical max wait", maxWait: 60 * time.Second, expected: 6 * time.Second},
+		{name: "zero max wait doesn't panic NewTicker", maxWait: 0, expected: time.Millisecond},
+		{name: "negative max wait doesn't panic NewTicker", maxWait: -1, expected: time.Millisecond},
+	}
+
+	for _, c := range cases {
+		actual := healthTickInterval(c.maxWait)
+		if actual != c.expected {
+			t.Errorf("%s: expected %v, got %v", c.name, c.expected, actual)
+		}
+		if actual <= 0 {
+			t.Errorf("%s: tick interval must be positive, got %v", c.name, actual)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+// kibosh
+//
+// Copyright (c) 2017-Present Pivotal Software, Inc. All Rights Reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may
+// obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the
+// License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helm
+
+import (
+	"context"
+
+	"code.cloudfoundry.org/lager"
+	"github.com/cf-platform-eng/kibosh/config"
+	"github.com/cf-platform-eng/kibosh/k8s"
+	"github.com/pkg/errors"
+)
+
+// MultiInstaller drives Tiller installation across every configured kube
+// context.
+type MultiInstaller interface {
+	Install() error
+	InstallContext(ctx context.Context) error
+	InstallerForSelector(selector map[string]string) (Installer, error)
+}
+
+type multiInstaller struct {
+	contexts   []config.ClusterContext
+	installers map[string]Installer
+	logger     lager.Logger
+}
+
+// NewMultiInstaller builds one Installer per configured context, backed by
+// the k8s.Cluster and MyHelmClient clusterFactory/clientFactory produce for
+// it.
+func NewMultiInstaller(
+	contexts []config.ClusterContext,
+	clusterFactory k8s.ClusterFactory,
+	clientFactory func(cluster k8s.Cluster) MyHelmClient,
+	registryConfig *config.RegistryConfig,
+	logger lager.Logger,
+) (MultiInstaller, error) {
+	installers := map[string]Installer{}
+	for _, cctx := range contexts {
+		cluster, err := clusterFactory.ClusterForContext(cctx.Name)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to build cluster for context %q", cctx.Name)
+		}
+
+		installers[cctx.Name] = NewInstaller(registryConfig, cluster, clientFactory(cluster), logger)
+	}
+
+	return &multiInstaller{
+		contexts:   contexts,
+		installers: installers,
+		logger:     logger,
+	}, nil
+}
+
+// Install is InstallContext with a background context.
+func (m *multiInstaller) Install() error {
+	return m.InstallContext(context.Background())
+}
+
+// InstallContext installs or upgrades Tiller on every configured cluster,
+// running every context even if one fails, and returns the first error.
+func (m *multiInstaller) InstallContext(ctx context.Context) error {
+	var firstErr error
+	for _, cctx := range m.contexts {
+		m.logger.Info("installing tiller for context", lager.Data{"context": cctx.Name})
+		if err := m.installers[cctx.Name].InstallContext(ctx); err != nil {
+			m.logger.Error("failed installing tiller for context", err, lager.Data{"context": cctx.Name})
+			if firstErr == nil {
+				firstErr = errors.Wrapf(err, "installing tiller for context %q", cctx.Name)
+			}
+		}
+	}
+	return firstErr
+}
+
+// InstallerForSelector returns the Installer whose context selector matches.
+func (m *multiInstaller) InstallerForSelector(selector map[string]string) (Installer, error) {
+	for _, cctx := range m.contexts {
+		if selectorMatches(cctx.Selector, selector) {
+			return m.installers[cctx.Name], nil
+		}
+	}
+	return nil, errors.Errorf("no cluster context matches selector %v", selector)
+}
+
+func selectorMatches(contextSelector map[string]string, requested map[string]string) bool {
+	for k, v := range requested {
+		if contextSelector[k] != v {
+			return false
+		}
+	}
+	return true
+}
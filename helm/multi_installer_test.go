@@ -0,0 +1,178 @@
+// kibosh
+//
+// Copyright (c) 2017-Present Pivotal Software, Inc. All Rights Reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may
+// obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the
+// License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helm
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"code.cloudfoundry.org/lager"
+	"github.com/cf-platform-eng/kibosh/config"
+	"github.com/cf-platform-eng/kibosh/k8s"
+	"github.com/pkg/errors"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// fakeInstaller is a minimal Installer fake used to exercise multiInstaller's
+// fan-out logic without needing a real k8s.Cluster/MyHelmClient.
+type fakeInstaller struct {
+	Installer
+	installCtx context.Context
+	installErr error
+	installedN int
+}
+
+func (f *fakeInstaller) InstallContext(ctx context.Context) error {
+	f.installCtx = ctx
+	f.installedN++
+	return f.installErr
+}
+
+func TestMultiInstallerInstallContext(t *testing.T) {
+	t.Run("threads ctx into every context's installer", func(t *testing.T) {
+		a := &fakeInstaller{}
+		b := &fakeInstaller{}
+		m := &multiInstaller{
+			contexts: []config.ClusterContext{{Name: "a"}, {Name: "b"}},
+			installers: map[string]Installer{
+				"a": a,
+				"b": b,
+			},
+			logger: lager.NewLogger("test"),
+		}
+
+		ctx := context.WithValue(context.Background(), struct{}{}, "marker")
+		if err := m.InstallContext(ctx); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if a.installCtx != ctx || b.installCtx != ctx {
+			t.Error("expected the same ctx to reach every context's installer")
+		}
+	})
+
+	t.Run("runs every context even when one fails, returns the first error", func(t *testing.T) {
+		a := &fakeInstaller{installErr: errors.New("boom")}
+		b := &fakeInstaller{}
+		m := &multiInstaller{
+			contexts: []config.ClusterContext{{Name: "a"}, {Name: "b"}},
+			installers: map[string]Installer{
+				"a": a,
+				"b": b,
+			},
+			logger: lager.NewLogger("test"),
+		}
+
+		if err := m.InstallContext(context.Background()); err == nil {
+			t.Fatal("expected the first error to be returned")
+		}
+		if b.installedN != 1 {
+			t.Error("expected the second context to still be installed after the first failed")
+		}
+	})
+
+	t.Run("Install wraps InstallContext with a background context", func(t *testing.T) {
+		a := &fakeInstaller{}
+		m := &multiInstaller{
+			contexts:   []config.ClusterContext{{Name: "a"}},
+			installers: map[string]Installer{"a": a},
+			logger:     lager.NewLogger("test"),
+		}
+
+		if err := m.Install(); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if a.installCtx == nil {
+			t.Fatal("expected Install to invoke InstallContext")
+		}
+	})
+}
+
+// TestNewMultiInstaller exercises the real k8s.ClusterFactory, not a fake, so
+// a broken Cluster constructor (e.g. ClusterForContext returning something
+// that doesn't compile or construct) fails here rather than only showing up
+// against a live cluster.
+func TestNewMultiInstaller(t *testing.T) {
+	cfg := clientcmdapi.NewConfig()
+	cfg.Clusters["test-cluster"] = &clientcmdapi.Cluster{Server: "https://127.0.0.1:6443"}
+	cfg.Contexts["test-context"] = &clientcmdapi.Context{Cluster: "test-cluster"}
+	cfg.CurrentContext = "test-context"
+
+	path := filepath.Join(t.TempDir(), "kubeconfig")
+	if err := clientcmd.WriteToFile(*cfg, path); err != nil {
+		t.Fatalf("unable to write test kubeconfig: %v", err)
+	}
+
+	contexts := []config.ClusterContext{{Name: "test-context"}}
+	clusterFactory := k8s.NewClusterFactory(path)
+	clientFactory := func(cluster k8s.Cluster) MyHelmClient {
+		return &fakeHelmClient{}
+	}
+
+	m, err := NewMultiInstaller(contexts, clusterFactory, clientFactory, &config.RegistryConfig{}, lager.NewLogger("test"))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	installer, err := m.(*multiInstaller).InstallerForSelector(map[string]string{})
+	if err != nil {
+		t.Fatalf("expected an installer for the configured context, got %v", err)
+	}
+	if installer == nil {
+		t.Fatal("expected a non-nil installer")
+	}
+}
+
+func TestSelectorMatches(t *testing.T) {
+	cases := []struct {
+		name            string
+		contextSelector map[string]string
+		requested       map[string]string
+		expected        bool
+	}{
+		{
+			name:            "empty requested selector always matches",
+			contextSelector: map[string]string{"env": "prod"},
+			requested:       map[string]string{},
+			expected:        true,
+		},
+		{
+			name:            "matching key/value",
+			contextSelector: map[string]string{"env": "prod", "region": "us"},
+			requested:       map[string]string{"env": "prod"},
+			expected:        true,
+		},
+		{
+			name:            "mismatched value",
+			contextSelector: map[string]string{"env": "prod"},
+			requested:       map[string]string{"env": "staging"},
+			expected:        false,
+		},
+		{
+			name:            "requested key absent from context selector",
+			contextSelector: map[string]string{"env": "prod"},
+			requested:       map[string]string{"region": "us"},
+			expected:        false,
+		},
+	}
+
+	for _, c := range cases {
+		if selectorMatches(c.contextSelector, c.requested) != c.expected {
+			t.Errorf("%s: expected %v", c.name, c.expected)
+		}
+	}
+}
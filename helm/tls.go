@@ -0,0 +1,111 @@
+// kibosh
+//
+// Copyright (c) 2017-Present Pivotal Software, Inc. All Rights Reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may
+// obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the
+// License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helm
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cf-platform-eng/kibosh/config"
+	"github.com/cf-platform-eng/kibosh/k8s"
+	"github.com/pkg/errors"
+	helmstaller "k8s.io/helm/cmd/helm/installer"
+)
+
+const tlsSecretName = "tiller-secret"
+
+// paths where the TLS secret above is mounted into the Tiller pod, fixed by
+// the chart this installer renders.
+const (
+	tillerTLSCertFile = "/etc/certs/tls.crt"
+	tillerTLSKeyFile  = "/etc/certs/tls.key"
+	tillerTLSCaFile   = "/etc/certs/ca.crt"
+)
+
+func (i *installer) SetTLSConfig(tlsConfig *config.TillerTLSConfig) {
+	i.tlsConfig = tlsConfig
+}
+
+// applyTLSOptions sets the EnableTLS/VerifyTLS/TLSCertFile/... fields on
+// options when TLS is enabled. Side-effect free, so dry-run can use it too.
+func (i *installer) applyTLSOptions(options *helmstaller.Options) {
+	if !i.tlsConfig.HasTillerTLSConfig() {
+		return
+	}
+
+	options.EnableTLS = true
+	options.VerifyTLS = i.tlsConfig.VerifyTLS
+	options.TLSCertFile = tillerTLSCertFile
+	options.TLSKeyFile = tillerTLSKeyFile
+	options.TLSCaCertFile = tillerTLSCaFile
+}
+
+// provisionTLS creates the Tiller TLS secret (if enabled), applies the
+// corresponding options fields, and reconfigures the helm client for mTLS.
+// Mutates the live cluster and client - dry-run callers use applyTLSOptions
+// instead.
+func (i *installer) provisionTLS(ctx context.Context, options *helmstaller.Options) error {
+	if !i.tlsConfig.HasTillerTLSConfig() {
+		return nil
+	}
+	if err := i.tlsConfig.Validate(); err != nil {
+		return err
+	}
+
+	i.applyTLSOptions(options)
+
+	secretSetup := k8s.NewTLSSecretSetup(nameSpace, tlsSecretName, i.cluster, i.tlsConfig)
+	if err := secretSetup.Setup(ctx); err != nil {
+		return errors.Wrap(err, "error provisioning tiller tls secret")
+	}
+
+	if err := i.client.ConfigureTLS(i.tlsConfig.ClientCert, i.tlsConfig.ClientKey, i.tlsConfig.CACert); err != nil {
+		return errors.Wrap(err, "error reconfiguring helm client for tls")
+	}
+
+	return nil
+}
+
+// RotateTLS regenerates the Tiller TLS secret and forces an Upgrade with the
+// new material. It bypasses InstallContext, which would no-op here since
+// tillerTag doesn't change on a rotation.
+func (i *installer) RotateTLS(ctx context.Context) error {
+	if !i.tlsConfig.HasTillerTLSConfig() {
+		return errors.New("tiller tls is not enabled, nothing to rotate")
+	}
+
+	tillerImage := "gcr.io/kubernetes-helm/tiller:" + tillerTag
+	if i.registryConfig.HasRegistryConfig() {
+		tillerImage = fmt.Sprintf("%s/tiller:%s", i.registryConfig.Server, tillerTag)
+	}
+
+	options := helmstaller.Options{
+		Namespace:      nameSpace,
+		ImageSpec:      tillerImage,
+		ServiceAccount: serviceAccount,
+		Replicas:       int(i.replicas),
+	}
+
+	if err := i.provisionTLS(ctx, &options); err != nil {
+		return err
+	}
+
+	if err := i.client.Upgrade(ctx, &options); err != nil {
+		return wrapPhaseErr(ctx, err, "rotate-tls")
+	}
+
+	return nil
+}
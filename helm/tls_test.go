@@ -0,0 +1,121 @@
+// kibosh
+//
+// Copyright (c) 2017-Present Pivotal Software, Inc. All Rights Reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may
+// obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the
+// License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helm
+
+import (
+	"context"
+	"testing"
+
+	"code.cloudfoundry.org/lager"
+	"github.com/cf-platform-eng/kibosh/config"
+	helmstaller "k8s.io/helm/cmd/helm/installer"
+)
+
+func completeTLSConfig() *config.TillerTLSConfig {
+	return &config.TillerTLSConfig{
+		Enabled:    true,
+		CACert:     "ca",
+		ServerCert: "server-cert",
+		ServerKey:  "server-key",
+		ClientCert: "client-cert",
+		ClientKey:  "client-key",
+	}
+}
+
+func TestApplyTLSOptions(t *testing.T) {
+	t.Run("disabled TLS leaves options untouched", func(t *testing.T) {
+		i := &installer{tlsConfig: &config.TillerTLSConfig{Enabled: false}}
+		options := helmstaller.Options{}
+		i.applyTLSOptions(&options)
+		if options.EnableTLS {
+			t.Error("expected EnableTLS to remain false")
+		}
+	})
+
+	t.Run("enabled TLS sets the tls option fields with no cluster/client access", func(t *testing.T) {
+		i := &installer{tlsConfig: &config.TillerTLSConfig{Enabled: true, VerifyTLS: true}}
+		options := helmstaller.Options{}
+
+		i.applyTLSOptions(&options)
+
+		if !options.EnableTLS {
+			t.Error("expected EnableTLS to be true")
+		}
+		if !options.VerifyTLS {
+			t.Error("expected VerifyTLS to be true")
+		}
+		if options.TLSCertFile == "" || options.TLSKeyFile == "" || options.TLSCaCertFile == "" {
+			t.Error("expected TLS file paths to be set")
+		}
+	})
+}
+
+func TestProvisionTLS(t *testing.T) {
+	t.Run("disabled TLS is a no-op", func(t *testing.T) {
+		cluster := &fakeCluster{}
+		client := &fakeHelmClient{}
+		i := &installer{tlsConfig: &config.TillerTLSConfig{Enabled: false}, cluster: cluster, client: client, logger: lager.NewLogger("test")}
+
+		if err := i.provisionTLS(context.Background(), &helmstaller.Options{}); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if cluster.createSecretCalls != 0 || client.configureTLSCalls != 0 {
+			t.Error("expected no cluster/client calls when TLS is disabled")
+		}
+	})
+
+	t.Run("enabled TLS replaces the secret and reconfigures the helm client", func(t *testing.T) {
+		cluster := &fakeCluster{}
+		client := &fakeHelmClient{}
+		i := &installer{tlsConfig: completeTLSConfig(), cluster: cluster, client: client, logger: lager.NewLogger("test")}
+
+		if err := i.provisionTLS(context.Background(), &helmstaller.Options{}); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if cluster.deleteSecretCalls != 1 || cluster.createSecretCalls != 1 {
+			t.Error("expected the tls secret to be deleted and recreated")
+		}
+		if client.configureTLSCalls != 1 {
+			t.Error("expected the helm client to be reconfigured for tls")
+		}
+	})
+}
+
+func TestRotateTLS(t *testing.T) {
+	t.Run("rejects rotation when tls isn't enabled", func(t *testing.T) {
+		i := &installer{tlsConfig: &config.TillerTLSConfig{Enabled: false}, logger: lager.NewLogger("test")}
+
+		if err := i.RotateTLS(context.Background()); err == nil {
+			t.Error("expected an error when tls is not enabled")
+		}
+	})
+
+	t.Run("forces an Upgrade with the rotated material instead of a no-op", func(t *testing.T) {
+		cluster := &fakeCluster{}
+		client := &fakeHelmClient{}
+		i := &installer{tlsConfig: completeTLSConfig(), cluster: cluster, client: client, logger: lager.NewLogger("test")}
+
+		if err := i.RotateTLS(context.Background()); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if client.upgradeCalls != 1 {
+			t.Error("expected RotateTLS to force an Upgrade with the new tls material")
+		}
+		if client.installCalls != 0 {
+			t.Error("expected RotateTLS not to go through Install")
+		}
+	})
+}
@@ -0,0 +1,35 @@
+// kibosh
+//
+// Copyright (c) 2017-Present Pivotal Software, Inc. All Rights Reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may
+// obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the
+// License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8s
+
+import (
+	"context"
+
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	api_v1 "k8s.io/client-go/pkg/api/v1"
+	"k8s.io/client-go/pkg/apis/extensions/v1beta1"
+)
+
+// Cluster is the subset of the Kubernetes API the helm package needs to drive
+// a Tiller install: reading back the deployment it rolled out, and
+// creating/replacing the secrets that carry TLS and private-registry
+// material. It's deliberately narrow - TLSSecretSetup and the installer are
+// the only callers.
+type Cluster interface {
+	GetDeployment(ctx context.Context, namespace string, name string, options meta_v1.GetOptions) (*v1beta1.Deployment, error)
+	CreateSecret(ctx context.Context, namespace string, secret *api_v1.Secret) (*api_v1.Secret, error)
+	DeleteSecret(ctx context.Context, namespace string, name string, options *meta_v1.DeleteOptions) error
+}
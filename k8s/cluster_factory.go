@@ -0,0 +1,50 @@
+// kibosh
+//
+// Copyright (c) 2017-Present Pivotal Software, Inc. All Rights Reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may
+// obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the
+// License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8s
+
+import (
+	"github.com/pkg/errors"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// ClusterFactory materializes a k8s.Cluster for a named kubeconfig context, so
+// callers that need to drive more than one cluster (e.g. a multi-context
+// Tiller install) don't each have to know how to load kubeconfig themselves.
+type ClusterFactory interface {
+	ClusterForContext(contextName string) (Cluster, error)
+}
+
+type clusterFactory struct {
+	kubeconfigPath string
+}
+
+func NewClusterFactory(kubeconfigPath string) ClusterFactory {
+	return &clusterFactory{
+		kubeconfigPath: kubeconfigPath,
+	}
+}
+
+func (f *clusterFactory) ClusterForContext(contextName string) (Cluster, error) {
+	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		&clientcmd.ClientConfigLoadingRules{ExplicitPath: f.kubeconfigPath},
+		&clientcmd.ConfigOverrides{CurrentContext: contextName},
+	).ClientConfig()
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to build client config for context %q", contextName)
+	}
+
+	return NewCluster(config)
+}
@@ -0,0 +1,68 @@
+// kibosh
+//
+// Copyright (c) 2017-Present Pivotal Software, Inc. All Rights Reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may
+// obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the
+// License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8s
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+func writeTestKubeconfig(t *testing.T, contextName string) string {
+	t.Helper()
+
+	cfg := clientcmdapi.NewConfig()
+	cfg.Clusters["test-cluster"] = &clientcmdapi.Cluster{Server: "https://127.0.0.1:6443"}
+	cfg.Contexts[contextName] = &clientcmdapi.Context{Cluster: "test-cluster"}
+	cfg.CurrentContext = contextName
+
+	path := filepath.Join(t.TempDir(), "kubeconfig")
+	if err := clientcmd.WriteToFile(*cfg, path); err != nil {
+		t.Fatalf("unable to write test kubeconfig: %v", err)
+	}
+	return path
+}
+
+func TestClusterFactoryClusterForContext(t *testing.T) {
+	t.Run("builds a real Cluster for a known context", func(t *testing.T) {
+		path := writeTestKubeconfig(t, "test-context")
+		factory := NewClusterFactory(path)
+
+		cluster, err := factory.ClusterForContext("test-context")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if cluster == nil {
+			t.Fatal("expected a non-nil Cluster")
+		}
+	})
+
+	t.Run("wraps the error for an unknown context", func(t *testing.T) {
+		path := writeTestKubeconfig(t, "test-context")
+		factory := NewClusterFactory(path)
+
+		_, err := factory.ClusterForContext("missing-context")
+		if err == nil {
+			t.Fatal("expected an error for an unknown context")
+		}
+		if !strings.HasPrefix(err.Error(), `unable to build client config for context "missing-context"`) {
+			t.Errorf("expected the error to be wrapped with the context name, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,58 @@
+// kibosh
+//
+// Copyright (c) 2017-Present Pivotal Software, Inc. All Rights Reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may
+// obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the
+// License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8s
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	api_v1 "k8s.io/client-go/pkg/api/v1"
+	"k8s.io/client-go/pkg/apis/extensions/v1beta1"
+	"k8s.io/client-go/rest"
+)
+
+// cluster is the Cluster implementation ClusterFactory hands back: a thin
+// wrapper around a real clientset. ctx isn't forwarded to the underlying
+// calls since this client-go vintage predates context-aware methods, but it
+// stays on the signature so callers can still be cancelled at the call site.
+type cluster struct {
+	clientset kubernetes.Interface
+}
+
+// NewCluster builds a Cluster backed by a real Kubernetes clientset for the
+// given rest.Config.
+func NewCluster(config *rest.Config) (Cluster, error) {
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to build kubernetes clientset")
+	}
+
+	return &cluster{clientset: clientset}, nil
+}
+
+func (c *cluster) GetDeployment(ctx context.Context, namespace string, name string, options meta_v1.GetOptions) (*v1beta1.Deployment, error) {
+	return c.clientset.ExtensionsV1beta1().Deployments(namespace).Get(name, options)
+}
+
+func (c *cluster) CreateSecret(ctx context.Context, namespace string, secret *api_v1.Secret) (*api_v1.Secret, error) {
+	return c.clientset.CoreV1().Secrets(namespace).Create(secret)
+}
+
+func (c *cluster) DeleteSecret(ctx context.Context, namespace string, name string, options *meta_v1.DeleteOptions) error {
+	return c.clientset.CoreV1().Secrets(namespace).Delete(name, options)
+}
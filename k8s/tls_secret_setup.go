@@ -0,0 +1,76 @@
+// kibosh
+//
+// Copyright (c) 2017-Present Pivotal Software, Inc. All Rights Reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may
+// obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the
+// License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8s
+
+import (
+	"context"
+
+	"github.com/cf-platform-eng/kibosh/config"
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	api_v1 "k8s.io/client-go/pkg/api/v1"
+)
+
+// TLSSecretSetup creates (or replaces) the Kubernetes Secret holding the CA
+// cert and the server cert/key pair Tiller needs to run with TLS enabled. The
+// client cert/key pair is not stored in it. It
+// mirrors PrivateRegistrySetup: a small, single-purpose helper the installer
+// calls before handing control to client.Install.
+type TLSSecretSetup struct {
+	namespace  string
+	secretName string
+	cluster    Cluster
+	tlsConfig  *config.TillerTLSConfig
+}
+
+func NewTLSSecretSetup(namespace string, secretName string, cluster Cluster, tlsConfig *config.TillerTLSConfig) *TLSSecretSetup {
+	return &TLSSecretSetup{
+		namespace:  namespace,
+		secretName: secretName,
+		cluster:    cluster,
+		tlsConfig:  tlsConfig,
+	}
+}
+
+// Setup creates the TLS secret, replacing any existing one so rotation is
+// just "generate new material, Setup again, Upgrade".
+func (s *TLSSecretSetup) Setup(ctx context.Context) error {
+	secret := &api_v1.Secret{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      s.secretName,
+			Namespace: s.namespace,
+		},
+		Type: api_v1.SecretTypeOpaque,
+		Data: map[string][]byte{
+			"ca.crt":  []byte(s.tlsConfig.CACert),
+			"tls.crt": []byte(s.tlsConfig.ServerCert),
+			"tls.key": []byte(s.tlsConfig.ServerKey),
+		},
+	}
+
+	err := s.cluster.DeleteSecret(ctx, s.namespace, s.secretName, &meta_v1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return errors.Wrap(err, "error removing existing tiller tls secret")
+	}
+
+	_, err = s.cluster.CreateSecret(ctx, s.namespace, secret)
+	if err != nil {
+		return errors.Wrap(err, "error creating tiller tls secret")
+	}
+
+	return nil
+}